@@ -0,0 +1,121 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import "sort"
+
+// uasPerMah is the number of microamp-seconds in one milliamp-hour
+// (1 mAh = 3.6 As = 3,600,000 uAs).
+const uasPerMah = 3_600_000.0
+
+// BatteryDischargePoint is one point on a battery's discharge curve: the
+// terminal Voltage observed at a given fraction of remaining charge (SoC,
+// 0.0 = empty, 1.0 = full).
+type BatteryDischargePoint struct {
+	SoC     float64
+	Voltage float64
+}
+
+// Battery models the power source attached to a DeviceModel or a single node,
+// so OTNS can estimate how long a node will run before it depletes.
+type Battery struct {
+	NominalVoltage     float64 // V
+	CapacityMah        float64 // mAh
+	InternalResistance float64 // Ohms; 0 disables its effect
+	DischargeCurve     []BatteryDischargePoint
+}
+
+// NewBattery creates a Battery, pre-sorting dischargeCurve by SoC so voltage
+// lookups don't need to re-sort on every call. dischargeCurve may be nil, in
+// which case NominalVoltage is used regardless of state of charge.
+func NewBattery(nominalVoltage, capacityMah, internalResistance float64, dischargeCurve []BatteryDischargePoint) *Battery {
+	b := &Battery{
+		NominalVoltage:     nominalVoltage,
+		CapacityMah:        capacityMah,
+		InternalResistance: internalResistance,
+		DischargeCurve:     append([]BatteryDischargePoint(nil), dischargeCurve...),
+	}
+	sort.Slice(b.DischargeCurve, func(i, j int) bool { return b.DischargeCurve[i].SoC < b.DischargeCurve[j].SoC })
+	return b
+}
+
+// capacityUas returns the battery's full capacity in microamp-seconds.
+func (b *Battery) capacityUas() float64 {
+	return b.CapacityMah * uasPerMah
+}
+
+// voltage returns the battery's open-circuit voltage at the given state of
+// charge, interpolating DischargeCurve if one is defined, or else
+// NominalVoltage.
+func (b *Battery) voltage(soc float64) float64 {
+	if len(b.DischargeCurve) == 0 {
+		return b.NominalVoltage
+	}
+
+	curve := b.DischargeCurve
+	if soc <= curve[0].SoC {
+		return curve[0].Voltage
+	}
+	if soc >= curve[len(curve)-1].SoC {
+		return curve[len(curve)-1].Voltage
+	}
+	for i := 1; i < len(curve); i++ {
+		if soc <= curve[i].SoC {
+			lo, hi := curve[i-1], curve[i]
+			frac := (soc - lo.SoC) / (hi.SoC - lo.SoC)
+			return lo.Voltage + frac*(hi.Voltage-lo.Voltage)
+		}
+	}
+	return curve[len(curve)-1].Voltage
+}
+
+// terminalVoltage returns the battery's voltage under load at the given
+// state of charge, applying the IR drop (V = V_oc - I*R) that
+// InternalResistance models. approxCurrentA is the current draw used to
+// compute that drop; since the actual current itself depends on the
+// (not-yet-known) terminal voltage, callers are expected to pass a current
+// estimated from the open-circuit voltage, which is accurate enough for the
+// small drops InternalResistance is meant to capture. A zero
+// InternalResistance makes this identical to voltage.
+func (b *Battery) terminalVoltage(soc, approxCurrentA float64) float64 {
+	v := b.voltage(soc) - approxCurrentA*b.InternalResistance
+	if v <= 0 {
+		// A resistance/current combination implying a non-positive terminal
+		// voltage isn't physical; fall back to the open-circuit voltage
+		// rather than returning something that would blow up 1/voltage.
+		return b.voltage(soc)
+	}
+	return v
+}
+
+// NodeDepletedEvent is emitted once, the moment a node's battery's state of
+// charge reaches zero, so higher layers (e.g. the simulation) can mark the
+// node as dead.
+type NodeDepletedEvent struct {
+	NodeId    int
+	Timestamp uint64
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+func TestBattery_VoltageFlatWithoutDischargeCurve(t *testing.T) {
+	b := NewBattery(3.3, 1000, 0, nil)
+
+	for _, soc := range []float64{0, 0.5, 1} {
+		if got := b.voltage(soc); got != 3.3 {
+			t.Errorf("voltage(%v) = %v, want NominalVoltage 3.3", soc, got)
+		}
+	}
+}
+
+func TestBattery_VoltageInterpolatesDischargeCurve(t *testing.T) {
+	b := NewBattery(3.3, 1000, 0, []BatteryDischargePoint{
+		{SoC: 1.0, Voltage: 3.3},
+		{SoC: 0.0, Voltage: 2.7}, // deliberately unsorted; NewBattery must sort it
+		{SoC: 0.5, Voltage: 3.0},
+	})
+
+	if got := b.voltage(0.25); math.Abs(got-2.85) > 1e-9 {
+		t.Errorf("voltage(0.25) = %v, want 2.85 (midway between the 0.0 and 0.5 points)", got)
+	}
+	if got := b.voltage(-1); got != 2.7 {
+		t.Errorf("voltage(-1) = %v, want clamped to the lowest point's 2.7", got)
+	}
+	if got := b.voltage(2); got != 3.3 {
+		t.Errorf("voltage(2) = %v, want clamped to the highest point's 3.3", got)
+	}
+}
+
+func TestBattery_TerminalVoltageAppliesIRDrop(t *testing.T) {
+	b := NewBattery(3.3, 1000, 2.0, nil) // 2 Ohm internal resistance
+
+	want := 3.3 - 0.1*2.0
+	if got := b.terminalVoltage(0.5, 0.1); math.Abs(got-want) > 1e-9 {
+		t.Errorf("terminalVoltage(0.5, 0.1A) = %v, want %v", got, want)
+	}
+
+	if got := b.terminalVoltage(0.5, 0); got != 3.3 {
+		t.Errorf("terminalVoltage() at zero current = %v, want open-circuit voltage 3.3 (zero IR drop)", got)
+	}
+}
+
+func TestBattery_TerminalVoltageFallsBackWhenNonPositive(t *testing.T) {
+	b := NewBattery(3.3, 1000, 100, nil) // large enough resistance to drive V below 0
+
+	if got := b.terminalVoltage(0.5, 1); got != 3.3 {
+		t.Errorf("terminalVoltage() with a non-physical drop = %v, want fallback to open-circuit voltage 3.3", got)
+	}
+}
+
+func newBatteryNode(battery *Battery) *NodeEnergy {
+	model := &DeviceModel{
+		Name:          "battery-test-model",
+		RxConsumption: 0.00002, // 20 mW
+		Battery:       battery,
+	}
+	txPower := DbValue(0)
+	node := newNode(1, 0, strPtr(""), &txPower)
+	node.Model = model
+	node.SetBattery(battery)
+	return node
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestNodeEnergy_BatteryDrainsAndDepletes(t *testing.T) {
+	battery := NewBattery(3.0, 1.0/3_600_000.0, 0, nil) // tiny capacity: 1 uAs
+	node := newBatteryNode(battery)
+
+	node.SetRadioState(RadioRx, 0)
+	node.SetRadioState(RadioRx, 1_000_000) // 1s of Rx, far more charge than the battery holds
+
+	if !node.Depleted {
+		t.Fatalf("node.Depleted = false, want true after draining more than the battery's capacity")
+	}
+	if got := node.RemainingCapacity(); got != 0 {
+		t.Errorf("RemainingCapacity() = %v, want 0 once depleted", got)
+	}
+	if got := node.StateOfCharge(); got != 0 {
+		t.Errorf("StateOfCharge() = %v, want 0 once depleted", got)
+	}
+
+	event := node.takeDepletionEvent()
+	if event == nil {
+		t.Fatalf("takeDepletionEvent() = nil, want a NodeDepletedEvent")
+	}
+	if node.takeDepletionEvent() != nil {
+		t.Errorf("takeDepletionEvent() after it was already taken should be nil")
+	}
+}
+
+func TestNodeEnergy_EstimatedLifetime(t *testing.T) {
+	battery := NewBattery(3.0, 1000, 0, nil)
+	node := newBatteryNode(battery)
+
+	if got := node.EstimatedLifetime(0); got != 0 {
+		t.Errorf("EstimatedLifetime(0) before any draw is recorded = %v, want 0", got)
+	}
+
+	// A 1000 mA (1,000,000 uA) draw depletes the 1000 mAh battery in one hour.
+	if got := node.EstimatedLifetime(1000 * uasPerMah / 3600); got <= 0 {
+		t.Errorf("EstimatedLifetime() with an explicit rate = %v, want > 0", got)
+	}
+}
+
+func TestNodeEnergy_NoBatteryReportsFullChargeAndZeroLifetime(t *testing.T) {
+	node := newBatteryNode(nil)
+
+	if got := node.RemainingCapacity(); got != 0 {
+		t.Errorf("RemainingCapacity() without a battery = %v, want 0", got)
+	}
+	if got := node.StateOfCharge(); got != 1.0 {
+		t.Errorf("StateOfCharge() without a battery = %v, want 1.0", got)
+	}
+	if got := node.EstimatedLifetime(0); got != 0 {
+		t.Errorf("EstimatedLifetime() without a battery = %v, want 0", got)
+	}
+}
@@ -28,21 +28,42 @@ package energy
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"sort"
+	"sync"
 
 	"github.com/openthread/ot-ns/logger"
 	. "github.com/openthread/ot-ns/radiomodel"
 )
 
 type EnergyAnalyser struct {
+	// mu guards the fields below against concurrent access from the metrics
+	// server goroutine started by StartMetricsServer.
+	mu                   sync.RWMutex
 	nodes                map[int]*NodeEnergy
 	networkHistory       []NetworkConsumption
 	energyHistoryByNodes [][]*NodeEnergy
 	title                string
+	depletionHandler     func(NodeDepletedEvent)
+	metricsServer        *http.Server
+}
+
+// SetDepletionHandler registers a callback invoked once per node depletion,
+// from the first StoreNetworkEnergy call after that node's battery's state of
+// charge reaches zero; the event's Timestamp records when it did. Pass nil to
+// unregister.
+func (e *EnergyAnalyser) SetDepletionHandler(handler func(NodeDepletedEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.depletionHandler = handler
 }
 
 func (e *EnergyAnalyser) AddNode(nodeID int, timestamp uint64, model *string, txPower *DbValue) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if _, ok := e.nodes[nodeID]; ok {
 		return
 	}
@@ -50,30 +71,48 @@ func (e *EnergyAnalyser) AddNode(nodeID int, timestamp uint64, model *string, tx
 }
 
 func (e *EnergyAnalyser) DeleteNode(nodeID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	delete(e.nodes, nodeID)
 
 	if len(e.nodes) == 0 {
-		e.ClearEnergyData()
+		e.clearEnergyData()
 	}
 }
 
 func (e *EnergyAnalyser) GetNode(nodeID int) *NodeEnergy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	return e.nodes[nodeID]
 }
 
 func (e *EnergyAnalyser) GetNetworkEnergyHistory() []NetworkConsumption {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	return e.networkHistory
 }
 
 func (e *EnergyAnalyser) GetEnergyHistoryByNodes() [][]*NodeEnergy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	return e.energyHistoryByNodes
 }
 
 func (e *EnergyAnalyser) GetLatestEnergyOfNodes() []*NodeEnergy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	return e.energyHistoryByNodes[len(e.energyHistoryByNodes)-1]
 }
 
 func (e *EnergyAnalyser) StoreNetworkEnergy(timestamp uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	nodesEnergySnapshot := make([]*NodeEnergy, 0, len(e.nodes))
 	networkSnapshot := NetworkConsumption{
 		Timestamp: timestamp,
@@ -82,19 +121,24 @@ func (e *EnergyAnalyser) StoreNetworkEnergy(timestamp uint64) {
 	netSize := float64(len(e.nodes))
 	for _, node := range e.nodes {
 		node.ComputeRadioState(timestamp)
+		if event := node.takeDepletionEvent(); event != nil && e.depletionHandler != nil {
+			e.depletionHandler(*event)
+		}
 
 		e := &NodeEnergy{
-			NodeId:   node.NodeId,
-			Disabled: node.CalculateDisabledEnergy(),
-			Sleep:    node.CalculateSleepEnergy(),
-			Tx:		  node.CalculateTxEnergy(),
-			Rx:		  node.CalculateRxEnergy(),
+			NodeId:     node.NodeId,
+			Disabled:   node.CalculateDisabledEnergy(),
+			Sleep:      node.CalculateSleepEnergy(),
+			Tx:         node.CalculateTxEnergy(),
+			Rx:         node.CalculateRxEnergy(),
+			Transition: node.CalculateTransitionEnergy(),
 		}
 
 		networkSnapshot.EnergyConsDisabled += e.Disabled / netSize
 		networkSnapshot.EnergyConsSleep += e.Sleep / netSize
 		networkSnapshot.EnergyConsTx += e.Tx / netSize
 		networkSnapshot.EnergyConsRx += e.Rx / netSize
+		networkSnapshot.EnergyConsTransition += e.Transition / netSize
 		nodesEnergySnapshot = append(nodesEnergySnapshot, e)
 	}
 
@@ -117,6 +161,9 @@ func (e *EnergyAnalyser) CreateEnergyResultsDir() {
 }
 
 func (e *EnergyAnalyser) SaveEnergyDataToTxtFile(name string, timestamp uint64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if name == "" {
 		if e.title == "" {
 			name = "energy"
@@ -151,6 +198,9 @@ func (e *EnergyAnalyser) SaveEnergyDataToTxtFile(name string, timestamp uint64)
 }
 
 func (e *EnergyAnalyser) SaveEnergyDataToCsvFile(name string, timestamp uint64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if name == "" {
 		if e.title == "" {
 			name = "energy"
@@ -186,7 +236,7 @@ func (e *EnergyAnalyser) SaveEnergyDataToCsvFile(name string, timestamp uint64)
 
 func (e *EnergyAnalyser) writeEnergyByNodesTxt(fileNodes *os.File, timestamp uint64) {
 	fmt.Fprintf(fileNodes, "Duration of the simulated network (in milliseconds): %d\n", timestamp/1000)
-	fmt.Fprintf(fileNodes, "ID\tDeviceModel\tDisabled (mJ)\tSleep (mJ)\tTransmiting (mJ)\tReceiving (mJ)\n")
+	fmt.Fprintf(fileNodes, "ID\tDeviceModel\tDisabled (mJ)\tSleep (mJ)\tTransmiting (mJ)\tReceiving (mJ)\tTransition (mJ)\tRemaining (mAh)\tSoC (%%)\n")
 
 	sortedNodes := make([]int, 0, len(e.nodes))
 	for id := range e.nodes {
@@ -196,20 +246,23 @@ func (e *EnergyAnalyser) writeEnergyByNodesTxt(fileNodes *os.File, timestamp uin
 
 	for _, id := range sortedNodes {
 		node := e.nodes[id]
-		fmt.Fprintf(fileNodes, "%d\t%s\t%f\t%f\t%f\t%f\n",
+		fmt.Fprintf(fileNodes, "%d\t%s\t%f\t%f\t%f\t%f\t%f\t%f\t%f\n",
 			id,
-			node.Model.GetName(),
+			node.DeviceModelName(),
 			node.CalculateDisabledEnergy(),
 			node.CalculateSleepEnergy(),
 			node.CalculateTxEnergy(),
 			node.CalculateRxEnergy(),
+			node.CalculateTransitionEnergy(),
+			node.RemainingCapacity(),
+			node.StateOfCharge()*100,
 		)
 	}
 }
 
 func (e *EnergyAnalyser) writeEnergyByNodesCsv(fileNodes *os.File, timestamp uint64) {
 	// fmt.Fprintf(fileNodes, "Duration of the simulated network (in milliseconds): %d\n", timestamp/1000)
-	fmt.Fprintf(fileNodes, "Node ID,Device Model,Disabled [mJ],Sleep [mJ],Transmiting [mJ],Receiving [mJ]\n")
+	fmt.Fprintf(fileNodes, "Node ID,Device Model,Disabled [mJ],Sleep [mJ],Transmiting [mJ],Receiving [mJ],Transition [mJ],Remaining [mAh],SoC [%%]\n")
 
 	sortedNodes := make([]int, 0, len(e.nodes))
 	for id := range e.nodes {
@@ -219,52 +272,69 @@ func (e *EnergyAnalyser) writeEnergyByNodesCsv(fileNodes *os.File, timestamp uin
 
 	for _, id := range sortedNodes {
 		node := e.nodes[id]
-		fmt.Fprintf(fileNodes, "%d,%s,%f,%f,%f,%f\n",
+		fmt.Fprintf(fileNodes, "%d,%s,%f,%f,%f,%f,%f,%f,%f\n",
 			id,
-			node.Model.Name,
+			node.DeviceModelName(),
 			node.CalculateDisabledEnergy(),
 			node.CalculateSleepEnergy(),
 			node.CalculateTxEnergy(),
 			node.CalculateRxEnergy(),
+			node.CalculateTransitionEnergy(),
+			node.RemainingCapacity(),
+			node.StateOfCharge()*100,
 		)
 	}
 }
 
 func (e *EnergyAnalyser) writeNetworkEnergyTxt(fileNetwork *os.File, timestamp uint64) {
 	fmt.Fprintf(fileNetwork, "Duration of the simulated network (in milliseconds): %d\n", timestamp/1000)
-	fmt.Fprintf(fileNetwork, "Time (ms)\tDisabled (mJ)\tSleep (mJ)\tTransmiting (mJ)\tReceiving (mJ)\n")
+	fmt.Fprintf(fileNetwork, "Time (ms)\tDisabled (mJ)\tSleep (mJ)\tTransmiting (mJ)\tReceiving (mJ)\tTransition (mJ)\n")
 	for _, snapshot := range e.networkHistory {
-		fmt.Fprintf(fileNetwork, "%d\t%f\t%f\t%f\t%f\n",
+		fmt.Fprintf(fileNetwork, "%d\t%f\t%f\t%f\t%f\t%f\n",
 			snapshot.Timestamp/1000,
 			snapshot.EnergyConsDisabled,
 			snapshot.EnergyConsSleep,
 			snapshot.EnergyConsTx,
 			snapshot.EnergyConsRx,
+			snapshot.EnergyConsTransition,
 		)
 	}
 }
 
 func (e *EnergyAnalyser) writeNetworkEnergyCsv(fileNetwork *os.File, timestamp uint64) {
 	// fmt.Fprintf(fileNetwork, "Duration of the simulated network (in milliseconds): %d\n", timestamp/1000)
-	fmt.Fprintf(fileNetwork, "Time [ms],Disabled [mJ],Sleep [mJ],Transmiting [mJ],Receiving [mJ]\n")
+	fmt.Fprintf(fileNetwork, "Time [ms],Disabled [mJ],Sleep [mJ],Transmiting [mJ],Receiving [mJ],Transition [mJ]\n")
 	for _, snapshot := range e.networkHistory {
-		fmt.Fprintf(fileNetwork, "%d,%f,%f,%f,%f\n",
+		fmt.Fprintf(fileNetwork, "%d,%f,%f,%f,%f,%f\n",
 			snapshot.Timestamp/1000,
 			snapshot.EnergyConsDisabled,
 			snapshot.EnergyConsSleep,
 			snapshot.EnergyConsTx,
 			snapshot.EnergyConsRx,
+			snapshot.EnergyConsTransition,
 		)
 	}
 }
 
 func (e *EnergyAnalyser) ClearEnergyData() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.clearEnergyData()
+}
+
+// clearEnergyData is the lock-free core of ClearEnergyData, for callers (like
+// DeleteNode) that already hold e.mu.
+func (e *EnergyAnalyser) clearEnergyData() {
 	logger.Debugf("Node's energy data cleared")
 	e.networkHistory = make([]NetworkConsumption, 0, 3600)
 	e.energyHistoryByNodes = make([][]*NodeEnergy, 0, 3600)
 }
 
 func (e *EnergyAnalyser) SetTitle(title string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	e.title = title
 }
 
@@ -280,14 +350,30 @@ func (e *EnergyAnalyser) GetAllDeviceModelNames() []string {
 
 // Set device model struct for specific node
 func (e *EnergyAnalyser) SetDeviceModel(nodeID int, model string) bool {
-	if _, ok := e.nodes[nodeID]; ok {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	node, ok := e.nodes[nodeID]
+	if !ok {
 		return false
 	}
-	if e.nodes[nodeID].SetDeviceModel(model) {
-		return true
-	} else {
+	return node.SetDeviceModel(model)
+}
+
+// SetDeviceModelAt changes nodeID's device model mid-simulation, effective at
+// timestamp. Unlike SetDeviceModel, it flushes energy accrued under the old
+// model into the node's legacy totals first, so CalculateXEnergy() keeps
+// reporting the node's correct combined total across the switch. It returns
+// false if nodeID or model is not found.
+func (e *EnergyAnalyser) SetDeviceModelAt(nodeID int, model string, timestamp uint64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	node, ok := e.nodes[nodeID]
+	if !ok {
 		return false
 	}
+	return node.SwitchDeviceModelAt(model, timestamp)
 }
 
 // Check if device model is listed in DeviceModels registry
@@ -26,6 +26,95 @@
 
 package energy
 
+import (
+	"math"
+	"sort"
+)
+
+// fallbackTxPowerConsumption is used only when a device model defines no
+// Tx power consumption samples at all, so there is nothing to interpolate from.
+const fallbackTxPowerConsumption = 0.000100000
+
+// interpolateTxPowerConsumption returns dm's energy consumption at txPower
+// (dBm), piecewise-linearly interpolating between the two nearest dBm points
+// dm defines consumption for. A txPower outside the defined range is clamped
+// to the nearest endpoint, or linearly extrapolated from the outermost two
+// samples, depending on dm.ExtrapolationMode.
+func (dm *DeviceModel) interpolateTxPowerConsumption(txPower int) float64 {
+	txList := make([]int, 0, len(dm.TxPowerConsumption))
+	for k := range dm.TxPowerConsumption {
+		txList = append(txList, k)
+	}
+	sort.Ints(txList)
+
+	if len(txList) == 0 {
+		return fallbackTxPowerConsumption
+	}
+	if len(txList) == 1 {
+		return dm.TxPowerConsumption[txList[0]]
+	}
+
+	lowest, highest := txList[0], txList[len(txList)-1]
+	if txPower <= lowest {
+		return dm.extrapolateOrClamp(txList, txPower, true)
+	}
+	if txPower >= highest {
+		return dm.extrapolateOrClamp(txList, txPower, false)
+	}
+
+	lowerIdx := 0
+	for i, k := range txList {
+		if k > txPower {
+			break
+		}
+		lowerIdx = i
+	}
+	x0, x1 := txList[lowerIdx], txList[lowerIdx+1]
+	if x0 == txPower {
+		return dm.TxPowerConsumption[x0]
+	}
+	return dm.interpolate(x0, x1, dm.TxPowerConsumption[x0], dm.TxPowerConsumption[x1], txPower)
+}
+
+// extrapolateOrClamp handles a txPower outside the range defined by txList.
+// atLow selects the two lowest-dBm samples (txPower is below them), otherwise
+// the two highest-dBm samples are used.
+func (dm *DeviceModel) extrapolateOrClamp(txList []int, txPower int, atLow bool) float64 {
+	if dm.ExtrapolationMode != ExtrapolationLinear {
+		if atLow {
+			return dm.TxPowerConsumption[txList[0]]
+		}
+		return dm.TxPowerConsumption[txList[len(txList)-1]]
+	}
+
+	var x0, x1 int
+	if atLow {
+		x0, x1 = txList[0], txList[1]
+	} else {
+		x0, x1 = txList[len(txList)-2], txList[len(txList)-1]
+	}
+	return dm.interpolate(x0, x1, dm.TxPowerConsumption[x0], dm.TxPowerConsumption[x1], txPower)
+}
+
+// interpolate linearly interpolates (or extrapolates) the consumption at
+// txPower between (x0,y0) and (x1,y1). When dm.InterpolationMode is
+// InterpolationPower, the interpolation fraction is computed in linear
+// milliwatt space instead of directly over dBm.
+func (dm *DeviceModel) interpolate(x0, x1 int, y0, y1 float64, txPower int) float64 {
+	if dm.InterpolationMode == InterpolationPower {
+		p0, p1, p := dbmToMw(x0), dbmToMw(x1), dbmToMw(txPower)
+		frac := (p - p0) / (p1 - p0)
+		return y0 + frac*(y1-y0)
+	}
+	frac := float64(txPower-x0) / float64(x1-x0)
+	return y0 + frac*(y1-y0)
+}
+
+// dbmToMw converts a power level in dBm to linear milliwatts.
+func dbmToMw(dbm int) float64 {
+	return math.Pow(10, float64(dbm)/10)
+}
+
 // Get device model Name
 func (dm *DeviceModel) GetName() string {
 	return dm.Name
@@ -0,0 +1,281 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openthread/ot-ns/logger"
+	. "github.com/openthread/ot-ns/radiomodel"
+	"gopkg.in/yaml.v3"
+)
+
+// radioStateNames maps the lower-case radio-state names used in catalog
+// files (transition_energy's from/to) to the RadioStates they denote.
+var radioStateNames = map[string]RadioStates{
+	"disabled": RadioDisabled,
+	"sleep":    RadioSleep,
+	"tx":       RadioTx,
+	"rx":       RadioRx,
+}
+
+// deviceModelCatalog is the on-disk representation of a user-supplied device-model
+// catalog file, as loaded by LoadDeviceModelsFromFile.
+type deviceModelCatalog struct {
+	Models []deviceModelEntry `yaml:"models" json:"models"`
+}
+
+// deviceModelEntry is a single model within a deviceModelCatalog. TxPowerConsumption
+// is keyed by dBm as a string because YAML/JSON map keys must be strings.
+type deviceModelEntry struct {
+	Name                string             `yaml:"name" json:"name"`
+	RxConsumption       float64            `yaml:"rx_consumption" json:"rx_consumption"`
+	SleepConsumption    float64            `yaml:"sleep_consumption" json:"sleep_consumption"`
+	DisabledConsumption float64            `yaml:"disabled_consumption" json:"disabled_consumption"`
+	TxPowerConsumption  map[string]float64 `yaml:"tx_power_consumption" json:"tx_power_consumption"`
+	ExtrapolationMode   string             `yaml:"extrapolation_mode" json:"extrapolation_mode"`
+
+	// InterpolationMode selects the axis space used between two defined
+	// TxPowerConsumption samples; see InterpolationMode. Defaults to "dbm".
+	InterpolationMode string `yaml:"interpolation_mode" json:"interpolation_mode"`
+
+	// Battery, if set, is this model's default power source. Omit it for
+	// models that shouldn't track remaining capacity/lifetime.
+	Battery *batteryEntry `yaml:"battery" json:"battery"`
+
+	// TransitionEnergy lists the non-instantaneous radio-state transitions
+	// this model incurs an extra energy cost for. Transitions not listed are
+	// treated as free/instantaneous.
+	TransitionEnergy []transitionCostEntry `yaml:"transition_energy" json:"transition_energy"`
+}
+
+// batteryEntry is the on-disk representation of a Battery.
+type batteryEntry struct {
+	NominalVoltage     float64                      `yaml:"nominal_voltage" json:"nominal_voltage"`
+	CapacityMah        float64                      `yaml:"capacity_mah" json:"capacity_mah"`
+	InternalResistance float64                      `yaml:"internal_resistance" json:"internal_resistance"`
+	DischargeCurve     []batteryDischargePointEntry `yaml:"discharge_curve" json:"discharge_curve"`
+}
+
+// batteryDischargePointEntry is the on-disk representation of a BatteryDischargePoint.
+type batteryDischargePointEntry struct {
+	SoC     float64 `yaml:"soc" json:"soc"`
+	Voltage float64 `yaml:"voltage" json:"voltage"`
+}
+
+// transitionCostEntry is the on-disk representation of one [from][to] entry
+// of DeviceModel.TransitionEnergy. From/To are one of "disabled", "sleep",
+// "tx", "rx", per radioStateNames.
+type transitionCostEntry struct {
+	From       string  `yaml:"from" json:"from"`
+	To         string  `yaml:"to" json:"to"`
+	DurationUs uint64  `yaml:"duration_us" json:"duration_us"`
+	Power      float64 `yaml:"power" json:"power"`
+}
+
+// LoadDeviceModelsFromFile parses a YAML or JSON device-model catalog at path (the
+// format is selected by file extension, .yaml/.yml or .json) and registers every
+// model it contains via RegisterDeviceModel. Loading a catalog is how new SoCs are
+// added to DeviceModels without recompiling OTNS. Wiring a CLI flag (or other
+// entry point) to call this is left to whatever exposes it to users; none exists
+// in this package yet.
+func LoadDeviceModelsFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read device-model catalog %s: %w", path, err)
+	}
+
+	var catalog deviceModelCatalog
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &catalog)
+	case ".json":
+		err = json.Unmarshal(data, &catalog)
+	default:
+		return fmt.Errorf("unsupported device-model catalog extension %q (want .yaml, .yml or .json)", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse device-model catalog %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(catalog.Models))
+	for _, entry := range catalog.Models {
+		if seen[entry.Name] {
+			return fmt.Errorf("device-model catalog %s: duplicate model name %q", path, entry.Name)
+		}
+		seen[entry.Name] = true
+
+		dm, err := entry.toDeviceModel()
+		if err != nil {
+			return fmt.Errorf("device-model catalog %s: %w", path, err)
+		}
+		if err := RegisterDeviceModel(dm); err != nil {
+			return fmt.Errorf("device-model catalog %s: %w", path, err)
+		}
+	}
+
+	logger.Infof("Loaded %d device model(s) from %s", len(catalog.Models), path)
+	return nil
+}
+
+// toDeviceModel validates and converts a deviceModelEntry into a *DeviceModel.
+func (e *deviceModelEntry) toDeviceModel() (*DeviceModel, error) {
+	if e.Name == "" {
+		return nil, fmt.Errorf("device model is missing a name")
+	}
+	if e.RxConsumption < 0 || e.SleepConsumption < 0 || e.DisabledConsumption < 0 {
+		return nil, fmt.Errorf("device model %q: consumption values must not be negative", e.Name)
+	}
+	if len(e.TxPowerConsumption) == 0 {
+		return nil, fmt.Errorf("device model %q: tx_power_consumption must not be empty", e.Name)
+	}
+
+	dm := &DeviceModel{
+		Name:                e.Name,
+		RxConsumption:       e.RxConsumption,
+		SleepConsumption:    e.SleepConsumption,
+		DisabledConsumption: e.DisabledConsumption,
+		TxPowerConsumption:  make(map[int]float64, len(e.TxPowerConsumption)),
+		ExtrapolationMode:   ExtrapolationMode(e.ExtrapolationMode),
+		InterpolationMode:   InterpolationMode(e.InterpolationMode),
+	}
+	if dm.ExtrapolationMode == "" {
+		dm.ExtrapolationMode = ExtrapolationClamp
+	}
+	if dm.InterpolationMode == "" {
+		dm.InterpolationMode = InterpolationDbm
+	}
+
+	for dbmStr, consumption := range e.TxPowerConsumption {
+		dbm, err := strconv.Atoi(dbmStr)
+		if err != nil {
+			return nil, fmt.Errorf("device model %q: invalid tx_power_consumption key %q, want integer dBm", e.Name, dbmStr)
+		}
+		if consumption < 0 {
+			return nil, fmt.Errorf("device model %q: tx_power_consumption[%d] must not be negative", e.Name, dbm)
+		}
+		dm.TxPowerConsumption[dbm] = consumption
+	}
+
+	if e.Battery != nil {
+		battery, err := e.Battery.toBattery()
+		if err != nil {
+			return nil, fmt.Errorf("device model %q: %w", e.Name, err)
+		}
+		dm.Battery = battery
+	}
+
+	if len(e.TransitionEnergy) > 0 {
+		transitionEnergy, err := toTransitionEnergy(e.TransitionEnergy)
+		if err != nil {
+			return nil, fmt.Errorf("device model %q: %w", e.Name, err)
+		}
+		dm.TransitionEnergy = transitionEnergy
+	}
+
+	return dm, nil
+}
+
+// toBattery validates and converts a batteryEntry into a *Battery.
+func (e *batteryEntry) toBattery() (*Battery, error) {
+	if e.NominalVoltage <= 0 {
+		return nil, fmt.Errorf("battery: nominal_voltage must be positive")
+	}
+	if e.CapacityMah <= 0 {
+		return nil, fmt.Errorf("battery: capacity_mah must be positive")
+	}
+	if e.InternalResistance < 0 {
+		return nil, fmt.Errorf("battery: internal_resistance must not be negative")
+	}
+
+	curve := make([]BatteryDischargePoint, 0, len(e.DischargeCurve))
+	for _, point := range e.DischargeCurve {
+		curve = append(curve, BatteryDischargePoint{SoC: point.SoC, Voltage: point.Voltage})
+	}
+
+	return NewBattery(e.NominalVoltage, e.CapacityMah, e.InternalResistance, curve), nil
+}
+
+// toTransitionEnergy validates and converts a list of transitionCostEntry into the
+// [from][to] map DeviceModel.TransitionEnergy expects.
+func toTransitionEnergy(entries []transitionCostEntry) (map[RadioStates]map[RadioStates]TransitionCost, error) {
+	transitionEnergy := make(map[RadioStates]map[RadioStates]TransitionCost, len(entries))
+	for _, entry := range entries {
+		from, ok := radioStateNames[entry.From]
+		if !ok {
+			return nil, fmt.Errorf("transition_energy: unknown \"from\" radio state %q", entry.From)
+		}
+		to, ok := radioStateNames[entry.To]
+		if !ok {
+			return nil, fmt.Errorf("transition_energy: unknown \"to\" radio state %q", entry.To)
+		}
+		if entry.Power < 0 {
+			return nil, fmt.Errorf("transition_energy: power for %s->%s must not be negative", entry.From, entry.To)
+		}
+
+		if transitionEnergy[from] == nil {
+			transitionEnergy[from] = make(map[RadioStates]TransitionCost)
+		}
+		transitionEnergy[from][to] = TransitionCost{DurationUs: entry.DurationUs, Power: entry.Power}
+	}
+	return transitionEnergy, nil
+}
+
+// RegisterDeviceModel adds model to the DeviceModels registry, keyed by its Name.
+// It is exported so test harnesses and other programmatic callers can add models
+// without going through a catalog file. Registering a name that already exists
+// (e.g. a built-in model) is allowed but logged, so overrides are never silent.
+func RegisterDeviceModel(model *DeviceModel) error {
+	if model == nil {
+		return fmt.Errorf("cannot register a nil device model")
+	}
+	if model.Name == "" {
+		return fmt.Errorf("cannot register a device model with an empty name")
+	}
+	if model.RxConsumption < 0 || model.SleepConsumption < 0 || model.DisabledConsumption < 0 {
+		return fmt.Errorf("device model %q: consumption values must not be negative", model.Name)
+	}
+	if len(model.TxPowerConsumption) == 0 {
+		return fmt.Errorf("device model %q: tx power consumption map must not be empty", model.Name)
+	}
+	for dbm, consumption := range model.TxPowerConsumption {
+		if consumption < 0 {
+			return fmt.Errorf("device model %q: tx power consumption at %d dBm must not be negative", model.Name, dbm)
+		}
+	}
+
+	if _, exists := DeviceModels[model.Name]; exists {
+		logger.Warnf("Device model %q already exists in the registry and is being overridden", model.Name)
+	}
+	DeviceModels[model.Name] = model
+	return nil
+}
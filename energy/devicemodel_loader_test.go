@@ -0,0 +1,218 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+func writeCatalogFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write test catalog %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadDeviceModelsFromFile_ValidYamlRoundTrip(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.yaml", `
+models:
+  - name: loader-test-yaml
+    rx_consumption: 0.00002
+    sleep_consumption: 0.000005
+    disabled_consumption: 0.0000002
+    extrapolation_mode: linear
+    interpolation_mode: power
+    tx_power_consumption:
+      "0": 0.00003
+      "10": 0.00005
+    battery:
+      nominal_voltage: 3.3
+      capacity_mah: 1000
+      internal_resistance: 0.1
+      discharge_curve:
+        - soc: 0.0
+          voltage: 2.7
+        - soc: 1.0
+          voltage: 3.3
+    transition_energy:
+      - from: sleep
+        to: rx
+        duration_us: 1000
+        power: 0.00005
+`)
+
+	if err := LoadDeviceModelsFromFile(path); err != nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error: %v", err)
+	}
+
+	dm, ok := DeviceModels["loader-test-yaml"]
+	if !ok {
+		t.Fatalf("DeviceModels[%q] not found after loading", "loader-test-yaml")
+	}
+
+	if dm.ExtrapolationMode != ExtrapolationLinear {
+		t.Errorf("ExtrapolationMode = %v, want %v", dm.ExtrapolationMode, ExtrapolationLinear)
+	}
+	if dm.InterpolationMode != InterpolationPower {
+		t.Errorf("InterpolationMode = %v, want %v", dm.InterpolationMode, InterpolationPower)
+	}
+	if got := dm.TxPowerConsumption[10]; got != 0.00005 {
+		t.Errorf("TxPowerConsumption[10] = %v, want %v", got, 0.00005)
+	}
+
+	if dm.Battery == nil {
+		t.Fatalf("Battery = nil, want a parsed battery")
+	}
+	if math.Abs(dm.Battery.NominalVoltage-3.3) > 1e-9 {
+		t.Errorf("Battery.NominalVoltage = %v, want 3.3", dm.Battery.NominalVoltage)
+	}
+	if math.Abs(dm.Battery.InternalResistance-0.1) > 1e-9 {
+		t.Errorf("Battery.InternalResistance = %v, want 0.1", dm.Battery.InternalResistance)
+	}
+
+	cost, ok := dm.TransitionEnergy[RadioSleep][RadioRx]
+	if !ok {
+		t.Fatalf("TransitionEnergy[sleep][rx] not found")
+	}
+	if cost.DurationUs != 1000 || cost.Power != 0.00005 {
+		t.Errorf("TransitionEnergy[sleep][rx] = %+v, want {DurationUs:1000 Power:0.00005}", cost)
+	}
+}
+
+func TestLoadDeviceModelsFromFile_ValidJson(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.json", `{
+		"models": [
+			{
+				"name": "loader-test-json",
+				"rx_consumption": 0.00002,
+				"sleep_consumption": 0.000005,
+				"disabled_consumption": 0.0000002,
+				"tx_power_consumption": {"0": 0.00003}
+			}
+		]
+	}`)
+
+	if err := LoadDeviceModelsFromFile(path); err != nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error: %v", err)
+	}
+	if _, ok := DeviceModels["loader-test-json"]; !ok {
+		t.Fatalf("DeviceModels[%q] not found after loading", "loader-test-json")
+	}
+}
+
+func TestLoadDeviceModelsFromFile_DuplicateName(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.yaml", `
+models:
+  - name: loader-test-dup
+    rx_consumption: 0.00002
+    sleep_consumption: 0.000005
+    disabled_consumption: 0.0000002
+    tx_power_consumption:
+      "0": 0.00003
+  - name: loader-test-dup
+    rx_consumption: 0.00002
+    sleep_consumption: 0.000005
+    disabled_consumption: 0.0000002
+    tx_power_consumption:
+      "0": 0.00003
+`)
+
+	if err := LoadDeviceModelsFromFile(path); err == nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error = nil, want an error for a duplicate model name")
+	}
+}
+
+func TestLoadDeviceModelsFromFile_NegativeConsumption(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.yaml", `
+models:
+  - name: loader-test-negative
+    rx_consumption: -0.00002
+    sleep_consumption: 0.000005
+    disabled_consumption: 0.0000002
+    tx_power_consumption:
+      "0": 0.00003
+`)
+
+	if err := LoadDeviceModelsFromFile(path); err == nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error = nil, want an error for negative rx_consumption")
+	}
+}
+
+func TestLoadDeviceModelsFromFile_EmptyTxPowerConsumption(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.yaml", `
+models:
+  - name: loader-test-empty-tx
+    rx_consumption: 0.00002
+    sleep_consumption: 0.000005
+    disabled_consumption: 0.0000002
+    tx_power_consumption: {}
+`)
+
+	if err := LoadDeviceModelsFromFile(path); err == nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error = nil, want an error for an empty tx_power_consumption map")
+	}
+}
+
+func TestLoadDeviceModelsFromFile_UnsupportedExtension(t *testing.T) {
+	path := writeCatalogFile(t, "catalog.txt", "models: []")
+
+	if err := LoadDeviceModelsFromFile(path); err == nil {
+		t.Fatalf("LoadDeviceModelsFromFile() error = nil, want an error for an unsupported extension")
+	}
+}
+
+func TestRegisterDeviceModel_OverrideOfExistingNameSucceeds(t *testing.T) {
+	const name = "loader-test-override"
+	original := &DeviceModel{
+		Name:               name,
+		RxConsumption:      0.00001,
+		TxPowerConsumption: map[int]float64{0: 0.00001},
+	}
+	if err := RegisterDeviceModel(original); err != nil {
+		t.Fatalf("RegisterDeviceModel() error: %v", err)
+	}
+
+	override := &DeviceModel{
+		Name:               name,
+		RxConsumption:      0.00002,
+		TxPowerConsumption: map[int]float64{0: 0.00002},
+	}
+	if err := RegisterDeviceModel(override); err != nil {
+		t.Fatalf("RegisterDeviceModel() error on override: %v", err)
+	}
+
+	if got := DeviceModels[name]; got != override {
+		t.Errorf("DeviceModels[%q] = %v, want the overriding model registered", name, got)
+	}
+}
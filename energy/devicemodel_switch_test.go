@@ -0,0 +1,88 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+func TestSetDeviceModel_InvertedCheckFixed(t *testing.T) {
+	ea := NewEnergyAnalyser()
+	model := "stm32wb55rg"
+	txPower := DbValue(0)
+	ea.AddNode(1, 0, &model, &txPower)
+
+	if !ea.SetDeviceModel(1, "xxx") {
+		t.Errorf("SetDeviceModel() = false for an existing node, want true")
+	}
+	if ea.SetDeviceModel(2, "xxx") {
+		t.Errorf("SetDeviceModel() = true for a non-existing node, want false")
+	}
+}
+
+func TestSetDeviceModelAt_SplitsEnergyAcrossModels(t *testing.T) {
+	other := "switch-test-other-model"
+	if err := RegisterDeviceModel(&DeviceModel{
+		Name:                other,
+		RxConsumption:       0.00002000,
+		SleepConsumption:    0.00000500,
+		DisabledConsumption: 0.00000020,
+		TxPowerConsumption:  map[int]float64{0: 0.00003000},
+	}); err != nil {
+		t.Fatalf("RegisterDeviceModel() error: %v", err)
+	}
+
+	ea := NewEnergyAnalyser()
+	model := "stm32wb55rg"
+	txPower := DbValue(0)
+	ea.AddNode(1, 0, &model, &txPower)
+	node := ea.GetNode(1)
+
+	const tenSecondsUs = 10_000_000
+
+	// 10s of Rx under the original model.
+	node.SetRadioState(RadioRx, 0)
+	node.SetRadioState(RadioRx, tenSecondsUs)
+
+	if !ea.SetDeviceModelAt(1, other, tenSecondsUs) {
+		t.Fatalf("SetDeviceModelAt() = false, want true")
+	}
+
+	// 10s more of Rx, now under the new model.
+	node.SetRadioState(RadioRx, 2*tenSecondsUs)
+
+	wantFirstPhase := DeviceModels["stm32wb55rg"].RxConsumption * tenSecondsUs
+	wantSecondPhase := DeviceModels[other].RxConsumption * tenSecondsUs
+	want := wantFirstPhase + wantSecondPhase
+
+	if got := node.CalculateRxEnergy(); math.Abs(got-want) > 1e-9 {
+		t.Errorf("CalculateRxEnergy() = %v, want %v (phase1 %v + phase2 %v)", got, want, wantFirstPhase, wantSecondPhase)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpolateTxPowerConsumption_ExactMatch(t *testing.T) {
+	dm := &DeviceModel{
+		TxPowerConsumption: map[int]float64{-10: 0.00002, 0: 0.00003, 10: 0.00005},
+	}
+
+	if got := dm.interpolateTxPowerConsumption(0); got != 0.00003 {
+		t.Errorf("interpolateTxPowerConsumption(0) = %v, want %v", got, 0.00003)
+	}
+}
+
+func TestInterpolateTxPowerConsumption_MidpointDbmSpace(t *testing.T) {
+	dm := &DeviceModel{
+		TxPowerConsumption: map[int]float64{0: 0.00002, 10: 0.00004},
+	}
+
+	want := 0.00003 // halfway in dBm space
+	if got := dm.interpolateTxPowerConsumption(5); math.Abs(got-want) > 1e-12 {
+		t.Errorf("interpolateTxPowerConsumption(5) = %v, want %v", got, want)
+	}
+}
+
+func TestInterpolateTxPowerConsumption_PowerSpace(t *testing.T) {
+	dm := &DeviceModel{
+		InterpolationMode:  InterpolationPower,
+		TxPowerConsumption: map[int]float64{0: 0.00002, 10: 0.00004},
+	}
+
+	p0, p1, p5 := dbmToMw(0), dbmToMw(10), dbmToMw(5)
+	frac := (p5 - p0) / (p1 - p0)
+	want := 0.00002 + frac*(0.00004-0.00002)
+
+	if got := dm.interpolateTxPowerConsumption(5); math.Abs(got-want) > 1e-12 {
+		t.Errorf("interpolateTxPowerConsumption(5) in power mode = %v, want %v", got, want)
+	}
+	if want == 0.00003 {
+		t.Fatalf("test is degenerate: power-space and dBm-space fractions coincide at the midpoint")
+	}
+}
+
+func TestExtrapolateOrClamp_DefaultClamps(t *testing.T) {
+	dm := &DeviceModel{
+		TxPowerConsumption: map[int]float64{0: 0.00002, 10: 0.00004},
+	}
+
+	if got := dm.interpolateTxPowerConsumption(-20); got != 0.00002 {
+		t.Errorf("below-range consumption = %v, want clamped to %v", got, 0.00002)
+	}
+	if got := dm.interpolateTxPowerConsumption(30); got != 0.00004 {
+		t.Errorf("above-range consumption = %v, want clamped to %v", got, 0.00004)
+	}
+}
+
+func TestExtrapolateOrClamp_Linear(t *testing.T) {
+	dm := &DeviceModel{
+		ExtrapolationMode:  ExtrapolationLinear,
+		TxPowerConsumption: map[int]float64{0: 0.00002, 10: 0.00004},
+	}
+
+	want := 0.00002 - 0.00002 // slope of 0.000002/dBm, extrapolated 10 dBm below the lowest sample
+	if got := dm.interpolateTxPowerConsumption(-10); math.Abs(got-want) > 1e-12 {
+		t.Errorf("linear extrapolation below range = %v, want %v", got, want)
+	}
+
+	want = 0.00004 + 0.00002
+	if got := dm.interpolateTxPowerConsumption(20); math.Abs(got-want) > 1e-12 {
+		t.Errorf("linear extrapolation above range = %v, want %v", got, want)
+	}
+}
+
+func TestInterpolateTxPowerConsumption_NoSamples(t *testing.T) {
+	dm := &DeviceModel{}
+
+	if got := dm.interpolateTxPowerConsumption(0); got != fallbackTxPowerConsumption {
+		t.Errorf("interpolateTxPowerConsumption() with no samples = %v, want fallback %v", got, fallbackTxPowerConsumption)
+	}
+}
+
+func TestInterpolateTxPowerConsumption_SingleSample(t *testing.T) {
+	dm := &DeviceModel{
+		TxPowerConsumption: map[int]float64{5: 0.00007},
+	}
+
+	for _, txPower := range []int{-10, 5, 10} {
+		if got := dm.interpolateTxPowerConsumption(txPower); got != 0.00007 {
+			t.Errorf("interpolateTxPowerConsumption(%d) with a single sample = %v, want %v", txPower, got, 0.00007)
+		}
+	}
+}
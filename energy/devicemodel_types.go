@@ -26,12 +26,72 @@
 
 package energy
 
+import (
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+// ExtrapolationMode selects how FindAndAddTxPowerConsumption handles a txPower
+// that falls outside the Tx powers a DeviceModel defines consumption for.
+type ExtrapolationMode string
+
+const (
+	// ExtrapolationClamp clamps an out-of-range txPower to the nearest defined
+	// endpoint's consumption value. This is the default when unset.
+	ExtrapolationClamp ExtrapolationMode = "clamp"
+
+	// ExtrapolationLinear extrapolates an out-of-range txPower using the slope
+	// of the outermost two defined samples.
+	ExtrapolationLinear ExtrapolationMode = "linear"
+)
+
+// InterpolationMode selects the x-axis space FindAndAddTxPowerConsumption
+// interpolates in when a txPower falls between two defined samples.
+type InterpolationMode string
+
+const (
+	// InterpolationDbm interpolates directly over the dBm axis. This is the
+	// default when unset.
+	InterpolationDbm InterpolationMode = "dbm"
+
+	// InterpolationPower converts dBm to linear milliwatts before computing
+	// the interpolation fraction, since dBm is logarithmic and a straight
+	// dBm-space interpolation misrepresents the underlying (linear) power
+	// draw. The fraction is applied directly to the consumption values.
+	InterpolationPower InterpolationMode = "power"
+)
+
 type DeviceModel struct {
 	Name                string
 	RxConsumption       float64
 	SleepConsumption    float64
 	DisabledConsumption float64
 	TxPowerConsumption  map[int]float64 // dBm -> kW
+
+	// ExtrapolationMode selects the behavior for txPower values outside the
+	// range defined in TxPowerConsumption. Defaults to ExtrapolationClamp.
+	ExtrapolationMode ExtrapolationMode
+
+	// InterpolationMode selects the axis space used to interpolate between two
+	// defined TxPowerConsumption samples. Defaults to InterpolationDbm.
+	InterpolationMode InterpolationMode
+
+	// Battery is the default power source for nodes running this model. It is
+	// optional (nil means no battery/lifetime tracking) and may be overridden
+	// per-node via NodeEnergy.SetBattery.
+	Battery *Battery
+
+	// TransitionEnergy holds the extra energy cost of switching radio state,
+	// indexed [from][to], for SoCs where a transition (e.g. sleep->rx ramp-up)
+	// is not instantaneous. A missing [from][to] entry means that transition
+	// is treated as instantaneous, i.e. zero extra cost.
+	TransitionEnergy map[RadioStates]map[RadioStates]TransitionCost
+}
+
+// TransitionCost is the time and power cost of a single radio state
+// transition.
+type TransitionCost struct {
+	DurationUs uint64
+	Power      float64 // kW
 }
 
 // TODO make CLI configurable
@@ -0,0 +1,169 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/openthread/ot-ns/logger"
+)
+
+// StartMetricsServer starts a long-running HTTP endpoint at addr that serves
+// per-node and network-wide energy metrics in Prometheus/OpenMetrics text
+// exposition format at /metrics, refreshed from the latest data each time
+// StoreNetworkEnergy runs. This lets users point Grafana (or any Prometheus
+// scraper) at a running simulation and watch energy distribution live,
+// instead of only getting a result once the file-based output is written at
+// the end. SaveEnergyDataToTxtFile/CsvFile keep writing their files as before.
+func (e *EnergyAnalyser) StartMetricsServer(addr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.metricsServer != nil {
+		return fmt.Errorf("energy metrics server is already running")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not start energy metrics server on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.writeMetrics)
+	server := &http.Server{Handler: mux}
+	e.metricsServer = server
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Energy metrics server stopped: %v", err)
+		}
+	}()
+
+	logger.Infof("Energy metrics server listening on http://%s/metrics", addr)
+	return nil
+}
+
+// StopMetricsServer shuts down a metrics server started by StartMetricsServer.
+// It is a no-op if no server is running.
+func (e *EnergyAnalyser) StopMetricsServer() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.metricsServer == nil {
+		return nil
+	}
+	err := e.metricsServer.Close()
+	e.metricsServer = nil
+	return err
+}
+
+// writeMetrics serves the current energy metrics in Prometheus text format.
+func (e *EnergyAnalyser) writeMetrics(w http.ResponseWriter, _ *http.Request) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	sortedNodes := make([]int, 0, len(e.nodes))
+	for id := range e.nodes {
+		sortedNodes = append(sortedNodes, id)
+	}
+	sort.Ints(sortedNodes)
+
+	writeGaugeHelp(w, "otns_node_energy_tx_mj", "Cumulative transmit energy consumed by the node, in millijoules.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_energy_tx_mj%s %f\n", nodeLabels(node), node.CalculateTxEnergy())
+	}
+
+	writeGaugeHelp(w, "otns_node_energy_rx_mj", "Cumulative receive energy consumed by the node, in millijoules.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_energy_rx_mj%s %f\n", nodeLabels(node), node.CalculateRxEnergy())
+	}
+
+	writeGaugeHelp(w, "otns_node_energy_sleep_mj", "Cumulative sleep-state energy consumed by the node, in millijoules.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_energy_sleep_mj%s %f\n", nodeLabels(node), node.CalculateSleepEnergy())
+	}
+
+	writeGaugeHelp(w, "otns_node_energy_disabled_mj", "Cumulative disabled-state energy consumed by the node, in millijoules.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_energy_disabled_mj%s %f\n", nodeLabels(node), node.CalculateDisabledEnergy())
+	}
+
+	writeGaugeHelp(w, "otns_node_tx_power_dbm", "Node's currently configured Tx power, in dBm.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_tx_power_dbm%s %d\n", nodeLabels(node), int(node.TxPower()))
+	}
+
+	writeGaugeHelp(w, "otns_node_radio_state", "Node's current radio state, as the underlying RadioStates enum value.")
+	for _, id := range sortedNodes {
+		node := e.nodes[id]
+		fmt.Fprintf(w, "otns_node_radio_state%s %d\n", nodeLabels(node), int(node.RadioState()))
+	}
+
+	if len(e.networkHistory) == 0 {
+		return
+	}
+	latest := e.networkHistory[len(e.networkHistory)-1]
+
+	writeCounterHelp(w, "otns_network_energy_disabled_mj", "Network-wide average disabled-state energy per node, in millijoules.")
+	fmt.Fprintf(w, "otns_network_energy_disabled_mj %f\n", latest.EnergyConsDisabled)
+
+	writeCounterHelp(w, "otns_network_energy_sleep_mj", "Network-wide average sleep-state energy per node, in millijoules.")
+	fmt.Fprintf(w, "otns_network_energy_sleep_mj %f\n", latest.EnergyConsSleep)
+
+	writeCounterHelp(w, "otns_network_energy_tx_mj", "Network-wide average transmit energy per node, in millijoules.")
+	fmt.Fprintf(w, "otns_network_energy_tx_mj %f\n", latest.EnergyConsTx)
+
+	writeCounterHelp(w, "otns_network_energy_rx_mj", "Network-wide average receive energy per node, in millijoules.")
+	fmt.Fprintf(w, "otns_network_energy_rx_mj %f\n", latest.EnergyConsRx)
+
+	writeCounterHelp(w, "otns_network_energy_transition_mj", "Network-wide average state-transition energy per node, in millijoules.")
+	fmt.Fprintf(w, "otns_network_energy_transition_mj %f\n", latest.EnergyConsTransition)
+}
+
+// nodeLabels renders the node_id/device_model label set shared by all
+// per-node metrics.
+func nodeLabels(node *NodeEnergy) string {
+	return fmt.Sprintf("{node_id=%q,device_model=%q}", fmt.Sprint(node.NodeId), node.DeviceModelName())
+}
+
+func writeGaugeHelp(w http.ResponseWriter, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func writeCounterHelp(w http.ResponseWriter, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+func TestWriteMetrics_ServesPerNodeAndNetworkMetrics(t *testing.T) {
+	ea := NewEnergyAnalyser()
+	model := "stm32wb55rg"
+	txPower := DbValue(0)
+	ea.AddNode(1, 0, &model, &txPower)
+
+	node := ea.GetNode(1)
+	node.SetRadioState(RadioRx, 0)
+	node.SetRadioState(RadioRx, 1_000_000)
+	ea.StoreNetworkEnergy(1_000_000)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	ea.writeMetrics(rec, req)
+
+	if got := rec.Code; got != 200 {
+		t.Fatalf("writeMetrics() status = %d, want 200", got)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"otns_node_energy_rx_mj",
+		"otns_node_tx_power_dbm",
+		"otns_node_radio_state",
+		"otns_network_energy_rx_mj",
+		"otns_network_energy_transition_mj",
+		`node_id="1"`,
+		`device_model="stm32wb55rg"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("writeMetrics() body missing %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestWriteMetrics_EmptyNetworkHistoryOmitsNetworkMetrics(t *testing.T) {
+	ea := NewEnergyAnalyser()
+	model := "stm32wb55rg"
+	txPower := DbValue(0)
+	ea.AddNode(1, 0, &model, &txPower)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	ea.writeMetrics(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "otns_node_energy_rx_mj") {
+		t.Errorf("writeMetrics() should still serve per-node metrics with no network history, got:\n%s", body)
+	}
+	if strings.Contains(body, "otns_network_energy_rx_mj") {
+		t.Errorf("writeMetrics() should omit network-wide metrics before any StoreNetworkEnergy call, got:\n%s", body)
+	}
+}
+
+// TestWriteMetrics_ConcurrentWithSetRadioState exercises the race this series'
+// review flagged: the metrics scrape goroutine and the simulation's direct
+// NodeEnergy mutation must not race. Run with -race to verify.
+func TestWriteMetrics_ConcurrentWithSetRadioState(t *testing.T) {
+	ea := NewEnergyAnalyser()
+	model := "stm32wb55rg"
+	txPower := DbValue(0)
+	ea.AddNode(1, 0, &model, &txPower)
+	node := ea.GetNode(1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := uint64(1); i <= 1000; i++ {
+			node.SetRadioState(RadioRx, i*1000)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			rec := httptest.NewRecorder()
+			ea.writeMetrics(rec, req)
+		}
+	}()
+
+	wg.Wait()
+}
@@ -27,7 +27,9 @@
 package energy
 
 import (
-	"sort"
+	"sync"
+	"time"
+
 	"github.com/openthread/ot-ns/logger"
 	. "github.com/openthread/ot-ns/radiomodel"
 	. "github.com/openthread/ot-ns/types"
@@ -39,14 +41,67 @@ type NodeEnergy struct {
 	radio   RadioStatus
 	txPower *DbValue
 
-	Disabled float64
-	Sleep    float64
-	Tx       float64
-	Rx       float64
+	// mu guards every field below (and Model/radio/txPower above) against
+	// concurrent access. The simulation's hot loop mutates a node through
+	// SetRadioState/ComputeRadioState directly on this pointer, while
+	// EnergyAnalyser's metrics server (see StartMetricsServer) reads nodes
+	// from its own goroutine, so every exported accessor/mutator on
+	// NodeEnergy takes mu itself; internal, already-locked call paths use the
+	// unexported "Locked" helpers instead of re-entering through them.
+	mu sync.Mutex
+
+	Disabled   float64
+	Sleep      float64
+	Tx         float64
+	Rx         float64
+	Transition float64 // energy (mJ) spent on state-transition overhead (e.g. wake-up ramp-up)
+
+	// txPowerCache holds interpolated/extrapolated Tx power consumption values
+	// this node has already looked up, keyed by dBm. It is per-node so that
+	// nodes sharing a DeviceModel never mutate each other's results.
+	txPowerCache map[int]float64
+
+	// Battery is this node's power source, if any. It defaults to Model.Battery
+	// and can be overridden per-node via SetBattery. A nil Battery means the
+	// node's remaining capacity/lifetime is not tracked.
+	Battery *Battery
+	// batteryChargeUas is the battery's remaining charge, in microamp-seconds.
+	batteryChargeUas float64
+	// avgCurrentUa is an exponential moving average of the instantaneous
+	// current draw, in microamps, used to project EstimatedLifetime.
+	avgCurrentUa float64
+	// Depleted becomes true once batteryChargeUas has reached zero.
+	Depleted bool
+	// depletionEvent holds a pending NodeDepletedEvent until takeDepletionEvent
+	// is called, e.g. by EnergyAnalyser.StoreNetworkEnergy.
+	depletionEvent *NodeDepletedEvent
+
+	// Legacy* hold energy (mJ) accrued under device models this node has since
+	// switched away from, flushed there by SwitchDeviceModelAt. The Calculate*
+	// methods add these onto whatever the current Model now accounts for, so a
+	// mid-simulation model change doesn't lose or misattribute energy already
+	// spent.
+	LegacyDisabled float64
+	LegacySleep    float64
+	LegacyTx       float64
+	LegacyRx       float64
 }
 
+// batteryEmaAlpha is the smoothing factor of the moving average current draw
+// used by EstimatedLifetime: higher values track recent draw more closely.
+const batteryEmaAlpha = 0.2
+
 // increase timeSpent for specific radio mode
 func (node *NodeEnergy) ComputeRadioState(timestamp uint64) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	node.computeRadioStateLocked(timestamp)
+}
+
+// computeRadioStateLocked is ComputeRadioState's body, for callers that
+// already hold node.mu.
+func (node *NodeEnergy) computeRadioStateLocked(timestamp uint64) {
 	delta := timestamp - node.radio.Timestamp
 	switch node.radio.State {
 	case RadioDisabled:
@@ -62,20 +117,109 @@ func (node *NodeEnergy) ComputeRadioState(timestamp uint64) {
 	default:
 		logger.Panicf("unknown radio state: %v", node.radio.State)
 	}
+	if node.Battery != nil && !node.Depleted {
+		node.updateBatteryChargeLocked(delta, timestamp)
+	}
 	node.radio.Timestamp = timestamp
 }
 
+// updateBatteryChargeLocked subtracts the charge drawn by the current radio
+// state over deltaUs microseconds from the battery's remaining charge,
+// updates the moving-average current draw, and emits a NodeDepletedEvent the
+// moment the charge reaches zero. Callers must hold node.mu.
+func (node *NodeEnergy) updateBatteryChargeLocked(deltaUs uint64, timestamp uint64) {
+	if deltaUs == 0 {
+		return
+	}
+	node.drainBatteryLocked(node.currentStatePowerKwLocked(), deltaUs, timestamp)
+}
+
+// drainBatteryLocked subtracts the charge drawn by powerKw sustained for
+// durationUs microseconds from the battery's remaining charge, updates the
+// moving-average current draw, and emits a NodeDepletedEvent the moment the
+// charge reaches zero. It is a no-op if the node has no battery or is
+// already depleted. Callers must hold node.mu.
+func (node *NodeEnergy) drainBatteryLocked(powerKw float64, durationUs uint64, timestamp uint64) {
+	if node.Battery == nil || node.Depleted {
+		return
+	}
+
+	soc := node.stateOfChargeLocked()
+	approxCurrentA := powerKw * 1000 / node.Battery.voltage(soc)
+	voltage := node.Battery.terminalVoltage(soc, approxCurrentA)
+	currentA := powerKw * 1000 / voltage
+	// uAs = A * us, since 1 uAs = 1A * 1us.
+	chargeUas := currentA * float64(durationUs)
+
+	node.batteryChargeUas -= chargeUas
+	if node.batteryChargeUas < 0 {
+		node.batteryChargeUas = 0
+	}
+	node.avgCurrentUa = node.avgCurrentUa*(1-batteryEmaAlpha) + currentA*1e6*batteryEmaAlpha
+
+	if node.batteryChargeUas == 0 {
+		node.Depleted = true
+		node.depletionEvent = &NodeDepletedEvent{NodeId: node.NodeId, Timestamp: timestamp}
+	}
+}
+
+// currentStatePowerKwLocked returns the power, in kW, the node's current
+// radio state draws under its device model. Callers must hold node.mu.
+func (node *NodeEnergy) currentStatePowerKwLocked() float64 {
+	switch node.radio.State {
+	case RadioDisabled:
+		return node.Model.DisabledConsumption
+	case RadioSleep:
+		return node.Model.SleepConsumption
+	case RadioRx:
+		return node.Model.RxConsumption
+	case RadioTx:
+		txPower := int(*node.txPower)
+		if consumption, ok := node.Model.TxPowerConsumption[txPower]; ok {
+			return consumption
+		}
+		return node.findAndAddTxPowerConsumptionLocked(txPower)
+	default:
+		return 0
+	}
+}
+
 func (node *NodeEnergy) SetRadioState(state RadioStates, timestamp uint64) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
 	//Mandatory: compute energy consumed by the radio first.
-	node.ComputeRadioState(timestamp)
+	node.computeRadioStateLocked(timestamp)
+	node.addTransitionEnergyLocked(node.radio.State, state, timestamp)
 	node.radio.State = state
 }
 
+// addTransitionEnergyLocked adds the wake-up/ramp-up energy cost of switching
+// from the "from" to the "to" radio state, per Model.TransitionEnergy, to the
+// Transition accumulator, and drains the corresponding charge from the
+// node's battery (if any), the same way steady-state consumption is drained
+// in updateBatteryChargeLocked. Transitions with no defined cost remain
+// free, i.e. instantaneous, matching the pre-existing behavior. Callers must
+// hold node.mu.
+func (node *NodeEnergy) addTransitionEnergyLocked(from, to RadioStates, timestamp uint64) {
+	costsFrom, ok := node.Model.TransitionEnergy[from]
+	if !ok {
+		return
+	}
+	cost, ok := costsFrom[to]
+	if !ok {
+		return
+	}
+	node.Transition += float64(cost.DurationUs) * cost.Power
+	node.drainBatteryLocked(cost.Power, cost.DurationUs, timestamp)
+}
+
 func newNode(nodeID int, timestamp uint64, model *string, txPower *DbValue) *NodeEnergy {
 	node := &NodeEnergy{
-		NodeId:  nodeID,
-		Model:   DeviceModels[*model],
-		txPower: txPower,
+		NodeId:       nodeID,
+		Model:        DeviceModels[*model],
+		txPower:      txPower,
+		txPowerCache: make(map[int]float64),
 		radio: RadioStatus{
 			State:         RadioDisabled,
 			SpentDisabled: 0.0,
@@ -85,79 +229,251 @@ func newNode(nodeID int, timestamp uint64, model *string, txPower *DbValue) *Nod
 			Timestamp:     timestamp,
 		},
 	}
+	if node.Model != nil && node.Model.Battery != nil {
+		node.SetBattery(node.Model.Battery)
+	}
 	return node
 }
 
+// SetBattery attaches (or replaces) this node's battery, overriding the one
+// on its DeviceModel if any, and resets the tracked remaining charge to full
+// capacity. Pass nil to stop tracking battery charge for this node.
+func (node *NodeEnergy) SetBattery(battery *Battery) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	node.Battery = battery
+	node.avgCurrentUa = 0
+	node.Depleted = false
+	node.depletionEvent = nil
+	if battery != nil {
+		node.batteryChargeUas = battery.capacityUas()
+	} else {
+		node.batteryChargeUas = 0
+	}
+}
+
+// RemainingCapacity returns the battery's remaining charge in mAh. It returns
+// 0 if the node has no battery attached.
+func (node *NodeEnergy) RemainingCapacity() float64 {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if node.Battery == nil {
+		return 0
+	}
+	return node.batteryChargeUas / uasPerMah
+}
+
+// StateOfCharge returns the battery's remaining charge as a fraction of its
+// full capacity, from 0.0 (depleted) to 1.0 (full). It returns 1.0 if the
+// node has no battery attached.
+func (node *NodeEnergy) StateOfCharge() float64 {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.stateOfChargeLocked()
+}
+
+// stateOfChargeLocked is StateOfCharge's body, for callers that already hold
+// node.mu.
+func (node *NodeEnergy) stateOfChargeLocked() float64 {
+	if node.Battery == nil || node.Battery.CapacityMah <= 0 {
+		return 1.0
+	}
+	return node.batteryChargeUas / node.Battery.capacityUas()
+}
+
+// EstimatedLifetime projects the remaining time until the battery depletes.
+// If rateUa is 0, the node's own moving-average current draw is used instead;
+// pass a non-zero rateUa (in microamps) to project under a hypothetical load
+// instead. It returns 0 if there is no battery, or the draw rate is zero or
+// unknown.
+func (node *NodeEnergy) EstimatedLifetime(rateUa float64) time.Duration {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if node.Battery == nil {
+		return 0
+	}
+	if rateUa == 0 {
+		rateUa = node.avgCurrentUa
+	}
+	if rateUa <= 0 {
+		return 0
+	}
+	return time.Duration(node.batteryChargeUas / rateUa * float64(time.Second))
+}
+
+// takeDepletionEvent returns and clears any NodeDepletedEvent raised since the
+// last call, or nil if the node hasn't (newly) depleted.
+func (node *NodeEnergy) takeDepletionEvent() *NodeDepletedEvent {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	event := node.depletionEvent
+	node.depletionEvent = nil
+	return event
+}
+
+// RadioState returns the node's current radio state.
+func (node *NodeEnergy) RadioState() RadioStates {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.radio.State
+}
+
+// TxPower returns the node's currently configured Tx power, in dBm.
+func (node *NodeEnergy) TxPower() DbValue {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return *node.txPower
+}
+
+// DeviceModelName returns the name of the device model the node is currently
+// running under.
+func (node *NodeEnergy) DeviceModelName() string {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.Model.GetName()
+}
+
 // Set device model struct for power consumption if model found in DeviceModels
 func (node *NodeEnergy) SetDeviceModel(model string) bool {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	dm, ok := DeviceModels[model]
+	if !ok || dm == nil {
+		return false // model not found
+	}
+	node.Model = dm
+	node.txPowerCache = make(map[int]float64)
+	return true
+}
+
+// SwitchDeviceModelAt changes the node's device model to model, effective at
+// timestamp, while keeping its accumulated energy totals correct. It first
+// flushes the time accrued under the current radio state (so energy spent
+// under the old model isn't lost), snapshots the resulting totals into the
+// Legacy* fields, then swaps in the new model and resets the per-state time
+// counters (and the Tx power interpolation cache, which is model-specific) so
+// further accumulation is computed under the new model. It returns false if
+// model is not found in DeviceModels.
+func (node *NodeEnergy) SwitchDeviceModelAt(model string, timestamp uint64) bool {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
 	dm, ok := DeviceModels[model]
 	if !ok || dm == nil {
 		return false // model not found
 	}
+
+	node.computeRadioStateLocked(timestamp) // flush time accrued under the old model
+
+	node.LegacyDisabled = node.calculateDisabledEnergyLocked()
+	node.LegacySleep = node.calculateSleepEnergyLocked()
+	node.LegacyTx = node.calculateTxEnergyLocked()
+	node.LegacyRx = node.calculateRxEnergyLocked()
+
 	node.Model = dm
+	node.radio.SpentDisabled = 0
+	node.radio.SpentSleep = 0
+	node.radio.SpentRx = 0
+	node.radio.SpentTx = make(SpentTxMap)
+	node.txPowerCache = make(map[int]float64)
+
 	return true
 }
 
 // Calculate total transmit‐energy used by a node at each power level
 // Energy [mJ] = Power [kW] * Time [us]
 func (node *NodeEnergy) CalculateTxEnergy() float64 {
-	var txEnergy float64
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.calculateTxEnergyLocked()
+}
+
+func (node *NodeEnergy) calculateTxEnergyLocked() float64 {
+	txEnergy := node.LegacyTx
 	for txPower, timeSpent := range node.radio.SpentTx {
 		consumption, ok := node.Model.TxPowerConsumption[txPower]
 		if !ok {
 			// Handle unlisted TX power consumption
-			consumption = node.FindAndAddTxPowerConsumption(txPower)
+			consumption = node.findAndAddTxPowerConsumptionLocked(txPower)
 		}
 		txEnergy += consumption * float64(timeSpent)
 	}
 	return txEnergy
 }
 
-// Function handles missing tx power consumption for specific Tx power of Device Model. 
-// It looks for nearest higher defined Tx power if input tx power undefined in device model tx power consumption
-// and adds it to tx device model consumption list. If nodes tx power is bigger then known max tx value
-// use maximum known tx power consumption.
-// Returns and extend the energy consumption used for specific txPower of device model.
+// Handles a Tx power not directly listed in the device model's Tx power
+// consumption map. It piecewise-linearly interpolates between the two
+// nearest defined dBm points (or extrapolates/clamps outside the defined
+// range, per Model.ExtrapolationMode), then caches the result in this node's
+// own txPowerCache so repeated lookups are cheap and concurrent nodes sharing
+// a Model never mutate each other's results.
 func (node *NodeEnergy) FindAndAddTxPowerConsumption(txPower int) float64 {
-	// Collect all defined tx power consumptions for specific device model 
-	txList := make([]int, 0, len(node.Model.TxPowerConsumption))
-	for k := range node.Model.TxPowerConsumption {
-		txList = append(txList, k)
-	}
-	sort.Ints(txList)
-
-	undefinedValue := 0.000100000 // value used when empty tx list or appropriate value not found
-
-	if len(txList) == 0 {
-		// Handle empty list of tx power consumptions
-		node.Model.SetTxPowerConsumption(txPower, undefinedValue)
-		return undefinedValue
-	} else if txPower > txList[len(txList)-1] {
-		// Handle if nodes tx power is bigger than defined in deviceModel 
-		maxVal := node.Model.TxPowerConsumption[txList[len(txList)-1]]
-		node.Model.SetTxPowerConsumption(txPower, maxVal)
-		return maxVal
-	} else {
-		for _, k := range txList {
-			// Finds the nearest higher defined Tx power in deviceModel 
-			if k > txPower {
-				firstHigherVal := node.Model.TxPowerConsumption[k]
-				node.Model.SetTxPowerConsumption(txPower, firstHigherVal)
-				return firstHigherVal
-			}
-		}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.findAndAddTxPowerConsumptionLocked(txPower)
+}
+
+// findAndAddTxPowerConsumptionLocked is FindAndAddTxPowerConsumption's body,
+// for callers that already hold node.mu.
+func (node *NodeEnergy) findAndAddTxPowerConsumptionLocked(txPower int) float64 {
+	if consumption, ok := node.txPowerCache[txPower]; ok {
+		return consumption
 	}
-	return undefinedValue
+
+	consumption := node.Model.interpolateTxPowerConsumption(txPower)
+	node.txPowerCache[txPower] = consumption
+	return consumption
 }
 
 func (node *NodeEnergy) CalculateRxEnergy() float64 {
-	return node.Model.RxConsumption * float64(node.radio.SpentRx)
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.calculateRxEnergyLocked()
+}
+
+func (node *NodeEnergy) calculateRxEnergyLocked() float64 {
+	return node.LegacyRx + node.Model.RxConsumption*float64(node.radio.SpentRx)
 }
 
 func (node *NodeEnergy) CalculateDisabledEnergy() float64 {
-	return node.Model.DisabledConsumption * float64(node.radio.SpentDisabled)
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.calculateDisabledEnergyLocked()
+}
+
+func (node *NodeEnergy) calculateDisabledEnergyLocked() float64 {
+	return node.LegacyDisabled + node.Model.DisabledConsumption*float64(node.radio.SpentDisabled)
 }
 
 func (node *NodeEnergy) CalculateSleepEnergy() float64 {
-	return node.Model.SleepConsumption * float64(node.radio.SpentSleep)
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.calculateSleepEnergyLocked()
+}
+
+func (node *NodeEnergy) calculateSleepEnergyLocked() float64 {
+	return node.LegacySleep + node.Model.SleepConsumption*float64(node.radio.SpentSleep)
+}
+
+// CalculateTransitionEnergy returns the cumulative state-transition (e.g.
+// wake-up ramp-up) energy the node has spent, in millijoules.
+func (node *NodeEnergy) CalculateTransitionEnergy() float64 {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	return node.Transition
 }
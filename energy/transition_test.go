@@ -0,0 +1,107 @@
+// Copyright (c) 2020-2026, The OTNS Authors.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+// 3. Neither the name of the copyright holder nor the
+//    names of its contributors may be used to endorse or promote products
+//    derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package energy
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/openthread/ot-ns/radiomodel"
+)
+
+func newTransitionTestModel(battery *Battery) *DeviceModel {
+	return &DeviceModel{
+		Name:          "transition-test-model",
+		RxConsumption: 0.00001,
+		Battery:       battery,
+		TransitionEnergy: map[RadioStates]map[RadioStates]TransitionCost{
+			RadioSleep: {
+				RadioRx: {DurationUs: 1000, Power: 0.00005}, // wake-up ramp-up
+			},
+		},
+	}
+}
+
+func newTransitionTestNode(model *DeviceModel) *NodeEnergy {
+	txPower := DbValue(0)
+	node := newNode(1, 0, strPtr(""), &txPower)
+	node.Model = model
+	node.radio.State = RadioSleep
+	node.SetBattery(model.Battery)
+	return node
+}
+
+func TestAddTransitionEnergy_AccumulatesDefinedCost(t *testing.T) {
+	node := newTransitionTestNode(newTransitionTestModel(nil))
+
+	want := 1000.0 * 0.00005
+	node.SetRadioState(RadioRx, 0)
+
+	if got := node.CalculateTransitionEnergy(); math.Abs(got-want) > 1e-12 {
+		t.Errorf("CalculateTransitionEnergy() after one sleep->rx transition = %v, want %v", got, want)
+	}
+
+	// A transition with no defined cost (rx->sleep here) stays free.
+	node.SetRadioState(RadioSleep, 0)
+	if got := node.CalculateTransitionEnergy(); math.Abs(got-want) > 1e-12 {
+		t.Errorf("CalculateTransitionEnergy() after an undefined transition = %v, want unchanged %v", got, want)
+	}
+}
+
+func TestAddTransitionEnergy_DrainsBattery(t *testing.T) {
+	battery := NewBattery(3.0, 1000, 0, nil)
+	node := newTransitionTestNode(newTransitionTestModel(battery))
+
+	before := node.RemainingCapacity()
+	node.SetRadioState(RadioRx, 0)
+	after := node.RemainingCapacity()
+
+	if !(after < before) {
+		t.Errorf("RemainingCapacity() after a transition with a defined cost = %v, want less than %v", after, before)
+	}
+
+	wantCurrentA := 0.00005 * 1000 / 3.0
+	wantDrainUas := wantCurrentA * 1000
+	wantRemainingMah := (battery.capacityUas() - wantDrainUas) / uasPerMah
+	if math.Abs(after-wantRemainingMah) > 1e-9 {
+		t.Errorf("RemainingCapacity() = %v, want %v", after, wantRemainingMah)
+	}
+}
+
+func TestAddTransitionEnergy_CanDepleteBattery(t *testing.T) {
+	tiny := NewBattery(3.0, 1.0/3_600_000.0, 0, nil) // 1 uAs capacity
+	node := newTransitionTestNode(newTransitionTestModel(tiny))
+
+	node.SetRadioState(RadioRx, 0) // transition cost alone far exceeds capacity
+
+	if !node.Depleted {
+		t.Errorf("node.Depleted = false, want true after a transition cost exceeding the battery's capacity")
+	}
+	if got := node.RemainingCapacity(); got != 0 {
+		t.Errorf("RemainingCapacity() = %v, want 0 once depleted", got)
+	}
+}